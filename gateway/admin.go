@@ -0,0 +1,33 @@
+package gateway
+
+import "github.com/Bruce-sz/mag/gateway/admin"
+
+// Routes implements admin.Source, translating the gateway's internal route
+// bookkeeping into the admin API's read-only view.
+func (ds *DefaultServer) Routes() []admin.RouteInfo {
+	routes := []admin.RouteInfo{}
+	for _, route := range ds.GetProxyRoutes() {
+		health := ds.GetBackendHealth(route.Name)
+
+		// list the route's configured backends rather than the load
+		// balancer's, so a backend removed by a failed health check still
+		// shows up with Healthy set to false.
+		configured := ds.routeBackends(route.Name)
+		backends := make([]admin.BackendInfo, 0, len(configured))
+		for _, backend := range configured {
+			info := admin.BackendInfo{URL: backend.URL.String()}
+			if healthy, ok := health[info.URL]; ok {
+				h := healthy
+				info.Healthy = &h
+			}
+			backends = append(backends, info)
+		}
+
+		info := admin.RouteInfo{Name: route.Name, Backends: backends}
+		if state, ok := ds.GetCircuitBreakerState(route.Name); ok {
+			info.CircuitBreaker = state
+		}
+		routes = append(routes, info)
+	}
+	return routes
+}
@@ -0,0 +1,80 @@
+// Package admin exposes a small HTTP API for inspecting and monitoring a
+// running gateway: route introspection, a liveness probe and, when
+// metrics are enabled, Prometheus metrics and a rolling JSON stats summary.
+//
+// The package intentionally has no dependency on the gateway package so it
+// can be mounted by DefaultServer without an import cycle; callers
+// implement Source to expose their routes.
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// BackendInfo is a single backend of a route, with its last known health if
+// the route has an active health check configured.
+type BackendInfo struct {
+	URL     string `json:"url"`
+	Healthy *bool  `json:"healthy,omitempty"`
+}
+
+// RouteInfo is a read-only snapshot of a single proxy route.
+type RouteInfo struct {
+	Name           string        `json:"name"`
+	Backends       []BackendInfo `json:"backends"`
+	CircuitBreaker string        `json:"circuitBreaker,omitempty"`
+}
+
+// Source is implemented by the server embedding the admin API.
+type Source interface {
+	Routes() []RouteInfo
+}
+
+// NewHandler builds the admin API router: /health and /api/routes are
+// always mounted; /metrics and /debug/stats are mounted only when metrics
+// is non-nil.
+func NewHandler(source Source, metrics *Metrics) http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/health", healthHandler).Methods("GET")
+	router.HandleFunc("/api/routes", routesHandler(source)).Methods("GET")
+	router.HandleFunc("/api/routes/{name}", routeHandler(source)).Methods("GET")
+
+	if metrics != nil {
+		router.Handle("/metrics", metrics.Handler()).Methods("GET")
+		router.HandleFunc("/debug/stats", metrics.StatsHandler).Methods("GET")
+	}
+
+	return router
+}
+
+func healthHandler(rw http.ResponseWriter, req *http.Request) {
+	rw.WriteHeader(http.StatusOK)
+	rw.Write([]byte("OK"))
+}
+
+func routesHandler(source Source) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		writeJSON(rw, source.Routes())
+	}
+}
+
+func routeHandler(source Source) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		name := mux.Vars(req)["name"]
+		for _, route := range source.Routes() {
+			if route.Name == name {
+				writeJSON(rw, route)
+				return
+			}
+		}
+		http.NotFound(rw, req)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(v)
+}
@@ -0,0 +1,124 @@
+// +build !nometrics
+
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/codegangsta/negroni"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics is a per-route request middleware recording both Prometheus
+// counters/histograms and a rolling in-memory summary served as JSON.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+
+	mu    sync.Mutex
+	stats map[string]*routeStats
+}
+
+type routeStats struct {
+	requests   int64
+	totalNanos int64
+	codes      map[int]int64
+}
+
+// RouteStatsSummary is the JSON shape served by StatsHandler for a single
+// route.
+type RouteStatsSummary struct {
+	Requests     int64            `json:"requests"`
+	MeanResponse string           `json:"meanResponse"`
+	StatusCodes  map[string]int64 `json:"statusCodes"`
+}
+
+// NewMetrics registers the gateway's Prometheus collectors and returns a
+// ready to use Metrics middleware.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "mag",
+			Name:      "requests_total",
+			Help:      "Total requests processed by the gateway, by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "mag",
+			Name:      "request_duration_seconds",
+			Help:      "Request duration in seconds, by route.",
+		}, []string{"route"}),
+		stats: map[string]*routeStats{},
+	}
+
+	prometheus.MustRegister(m.requests, m.duration)
+	return m
+}
+
+// Handler serves the Prometheus text exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Observe returns a negroni.Handler that records the request/response for
+// routeName; it is meant to be the first handler in a route's middleware
+// chain so it sees the same status code and latency the client does.
+func (m *Metrics) Observe(routeName string) negroni.Handler {
+	return negroni.HandlerFunc(func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		nrw := negroni.NewResponseWriter(rw)
+		start := time.Now()
+
+		next(nrw, req)
+
+		elapsed := time.Since(start)
+		status := nrw.Status()
+
+		m.requests.WithLabelValues(routeName, req.Method, strconv.Itoa(status)).Inc()
+		m.duration.WithLabelValues(routeName).Observe(elapsed.Seconds())
+		m.record(routeName, status, elapsed)
+	})
+}
+
+func (m *Metrics) record(routeName string, status int, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.stats[routeName]
+	if !ok {
+		stats = &routeStats{codes: map[int]int64{}}
+		m.stats[routeName] = stats
+	}
+	stats.requests++
+	stats.totalNanos += elapsed.Nanoseconds()
+	stats.codes[status]++
+}
+
+// StatsHandler serves a JSON summary of request counts, a status code
+// histogram and mean response time per route.
+func (m *Metrics) StatsHandler(rw http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	summary := make(map[string]RouteStatsSummary, len(m.stats))
+	for route, stats := range m.stats {
+		mean := time.Duration(0)
+		if stats.requests > 0 {
+			mean = time.Duration(stats.totalNanos / stats.requests)
+		}
+
+		codes := make(map[string]int64, len(stats.codes))
+		for code, count := range stats.codes {
+			codes[strconv.Itoa(code)] = count
+		}
+
+		summary[route] = RouteStatsSummary{
+			Requests:     stats.requests,
+			MeanResponse: mean.String(),
+			StatusCodes:  codes,
+		}
+	}
+	m.mu.Unlock()
+
+	writeJSON(rw, summary)
+}
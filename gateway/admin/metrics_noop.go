@@ -0,0 +1,37 @@
+// +build nometrics
+
+package admin
+
+import (
+	"net/http"
+
+	"github.com/codegangsta/negroni"
+)
+
+// Metrics is a no-op stand-in used when the gateway is built with the
+// nometrics tag, so prometheus/client_golang doesn't need to be vendored.
+type Metrics struct{}
+
+// NewMetrics returns a Metrics that records nothing.
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+// Handler reports that metrics were compiled out.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "metrics disabled (built with nometrics)", http.StatusNotImplemented)
+	})
+}
+
+// Observe is a passthrough; no request data is recorded.
+func (m *Metrics) Observe(routeName string) negroni.Handler {
+	return negroni.HandlerFunc(func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		next(rw, req)
+	})
+}
+
+// StatsHandler reports that metrics were compiled out.
+func (m *Metrics) StatsHandler(rw http.ResponseWriter, req *http.Request) {
+	http.Error(rw, "metrics disabled (built with nometrics)", http.StatusNotImplemented)
+}
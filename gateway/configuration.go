@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"github.com/codegangsta/negroni"
+
+	"github.com/Bruce-sz/mag/gateway/provider"
+)
+
+// ServerConfiguration holds the options used to build a DefaultServer.
+type ServerConfiguration struct {
+	// EntryPoints are the addresses the gateway listens on. If empty, a
+	// single plain HTTP entry point named "default" is created on :8080.
+	EntryPoints []EntryPointConfiguration
+	Middleware  []negroni.Handler
+
+	// Providers are started by DefaultServer.Start and feed discovered
+	// proxy routes into ConfigureProxyRoutes as they change.
+	Providers []provider.Provider
+
+	// AdminAddress, when set, mounts the admin API (route introspection,
+	// /health, and optionally /metrics and /debug/stats) on its own entry
+	// point separate from the proxying entry points.
+	AdminAddress string
+
+	// Metrics enables the Prometheus metrics middleware and the
+	// /debug/stats endpoint on the admin API.
+	Metrics bool
+}
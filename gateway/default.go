@@ -1,39 +1,80 @@
 package gateway
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/codegangsta/negroni"
 	"github.com/gorilla/mux"
 	"github.com/meatballhat/negroni-logrus"
 	"github.com/pkg/errors"
+	"github.com/vulcand/oxy/buffer"
 	"github.com/vulcand/oxy/cbreaker"
+	"github.com/vulcand/oxy/connlimit"
 	"github.com/vulcand/oxy/forward"
+	"github.com/vulcand/oxy/ratelimit"
 	"github.com/vulcand/oxy/roundrobin"
-	"github.com/vulcand/oxy/stream"
+	"github.com/vulcand/oxy/utils"
 
-	log "github.com/Sirupsen/logrus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Bruce-sz/mag/gateway/admin"
+	"github.com/Bruce-sz/mag/gateway/provider"
 )
 
+// providerDebouncePeriod is the quiet period after the last received
+// ConfigMessage before the merged provider configuration is applied, so a
+// burst of events (e.g. a container stack starting up) triggers a single
+// reconfiguration instead of one per message.
+const providerDebouncePeriod = 3 * time.Second
+
+// defaultDrainTimeout bounds how long Stop waits for in-flight requests to
+// finish before forcibly closing an entry point's connections.
+const defaultDrainTimeout = 10 * time.Second
+
 // DefaultServer is the default gateway server implementation
 type DefaultServer struct {
-	server        *http.Server
-	router        *mux.Router
-	proxyRoutes   map[string]*roundrobin.RoundRobin
-	middleware    []negroni.Handler
-	configuration *ServerConfiguration
+	entryPoints     map[string]*serverEntryPoint
+	proxyRoutes     map[string]*roundrobin.RoundRobin
+	routeHandlers   map[string]http.Handler
+	routeDefs       map[string]*ProxyRoute
+	circuitBreakers map[string]*cbreaker.CircuitBreaker
+	middleware      []negroni.Handler
+	configuration   *ServerConfiguration
+
+	// routesMu guards proxyRoutes, routeHandlers, routeDefs,
+	// circuitBreakers and healthCheckers, which are read and written from
+	// the provider reconciliation goroutine, the SIGHUP reload goroutine
+	// and every per-route health checker goroutine.
+	routesMu sync.Mutex
+
+	configChan      chan provider.ConfigMessage
+	providerConfigs map[string][]*provider.Route
+	providerPool    *provider.SafePool
+	providersMu     sync.Mutex
+
+	healthCheckers map[string]chan struct{}
+	backendHealth  map[string]map[string]bool
+	healthMu       sync.Mutex
+
+	admin        *serverEntryPoint
+	adminMetrics *admin.Metrics
+
+	signalChan chan os.Signal
 }
 
-// NewDefaultServer creates a new DefaultServer. If the router parameter is nil
-// the method will create a new router. If the middleware parameter is nil the
-// method will use a request id, logger and a recovery middleware.
+// NewDefaultServer creates a new DefaultServer from one or more entry
+// points. If config.Middleware is empty the method will use a request
+// id, logger and a recovery middleware.
 func NewDefaultServer(config *ServerConfiguration) *DefaultServer {
-	router := config.Router
-	if router == nil {
-		router = mux.NewRouter()
-	}
-
 	middleware := config.Middleware
 	if len(middleware) <= 0 {
 		middleware = append(middleware, NewRequestID())
@@ -41,110 +82,261 @@ func NewDefaultServer(config *ServerConfiguration) *DefaultServer {
 		middleware = append(middleware, negroni.NewRecovery())
 	}
 
-	addr := config.Address
-	if addr == "" {
-		addr = ":8080"
+	entryPointConfigs := config.EntryPoints
+	if len(entryPointConfigs) == 0 {
+		entryPointConfigs = []EntryPointConfiguration{{Name: "default", Address: ":8080"}}
 	}
 
-	server := &http.Server{
-		Addr:    addr,
-		Handler: router,
+	entryPoints := map[string]*serverEntryPoint{}
+	for _, epConfig := range entryPointConfigs {
+		ep, err := newServerEntryPoint(epConfig)
+		if err != nil {
+			log.Fatalln("failed to create entrypoint", epConfig.Name, ":", err)
+		}
+		log.Debugln("creating new gateway entrypoint", ep.name, "for", epConfig.Address)
+		entryPoints[ep.name] = ep
+	}
+
+	server := &DefaultServer{
+		entryPoints:     entryPoints,
+		proxyRoutes:     map[string]*roundrobin.RoundRobin{},
+		routeHandlers:   map[string]http.Handler{},
+		routeDefs:       map[string]*ProxyRoute{},
+		circuitBreakers: map[string]*cbreaker.CircuitBreaker{},
+		middleware:      middleware,
+		configuration:   config,
+		configChan:      make(chan provider.ConfigMessage, 100),
+		providerConfigs: map[string][]*provider.Route{},
+		healthCheckers:  map[string]chan struct{}{},
+		backendHealth:   map[string]map[string]bool{},
+		signalChan:      make(chan os.Signal, 1),
 	}
 
-	log.Debugln("creating new gateway server for", addr)
-	return &DefaultServer{
-		server:        server,
-		router:        router,
-		proxyRoutes:   map[string]*roundrobin.RoundRobin{},
-		middleware:    middleware,
-		configuration: config,
+	if config.AdminAddress != "" {
+		if config.Metrics {
+			server.adminMetrics = admin.NewMetrics()
+		}
+
+		adminEP, err := newServerEntryPoint(EntryPointConfiguration{Name: "admin", Address: config.AdminAddress})
+		if err != nil {
+			log.Fatalln("failed to create admin entrypoint:", err)
+		}
+		adminEP.switcher.Switch(admin.NewHandler(server, server.adminMetrics))
+		server.admin = adminEP
 	}
+
+	return server
 }
 
+// updateProxyRoute reconciles an existing route with its latest definition:
+// it syncs lb's backends (so a changed weight on an already-registered
+// backend still takes effect, not just additions/removals), then rebuilds
+// the middleware chain and health checker the same way addProxyRoute would,
+// so fields like RateLimit, MaxConns or CircuitBreaker that only changed in
+// proxyRoute also take effect on an update rather than being frozen at
+// creation time.
 func (ds *DefaultServer) updateProxyRoute(proxyRoute *ProxyRoute, lb *roundrobin.RoundRobin) error {
 	log.Debugln("update proxy route for service", proxyRoute.Name)
 	servers := lb.Servers()
-	for _, url := range proxyRoute.Backends {
-		if !ContainsURL(servers, url) {
-			log.Infoln("register new backend", url)
-			lb.UpsertServer(url)
+	for _, backend := range proxyRoute.Backends {
+		if !ds.isBackendHealthy(proxyRoute.Name, backend.URL) {
+			continue
+		}
+		if ContainsURL(servers, backend.URL) {
+			log.Debugln("update backend weight for", backend.URL)
+		} else {
+			log.Infoln("register new backend", backend.URL)
+		}
+		if err := lb.UpsertServer(backend.URL, roundrobin.Weight(weightOrDefault(backend.Weight))); err != nil {
+			return errors.Wrapf(err, "failed to upsert server for %s", backend.URL.String())
 		}
 	}
 	for _, url := range servers {
-		if !ContainsURL(proxyRoute.Backends, url) {
+		if !ContainsBackendURL(proxyRoute.Backends, url) {
 			log.Infoln("unregister backend", url)
 			lb.RemoveServer(url)
 		}
 	}
+
+	top, breaker, err := ds.buildRouteChain(proxyRoute, lb)
+	if err != nil {
+		return errors.Wrapf(err, "failed to configure middleware chain for service %s", proxyRoute.Name)
+	}
+
+	middleware := ds.createMiddleware(proxyRoute.Name, lb)
+	middleware.UseHandler(top)
+	ds.routeHandlers[proxyRoute.Name] = middleware
+	ds.routeDefs[proxyRoute.Name] = proxyRoute
+	ds.circuitBreakers[proxyRoute.Name] = breaker
+
+	if stop, ok := ds.healthCheckers[proxyRoute.Name]; ok {
+		close(stop)
+		delete(ds.healthCheckers, proxyRoute.Name)
+	}
+	if proxyRoute.HealthCheck != nil {
+		stop := make(chan struct{})
+		ds.healthCheckers[proxyRoute.Name] = stop
+		checker := newHealthChecker(ds, proxyRoute.Name, proxyRoute.HealthCheck, lb)
+		go checker.run(stop)
+	}
+
 	return nil
 }
 
+func weightOrDefault(weight int) int {
+	if weight <= 0 {
+		return 1
+	}
+	return weight
+}
+
 func (ds *DefaultServer) addProxyRoute(proxyRoute *ProxyRoute) (*roundrobin.RoundRobin, error) {
 	log.Debugln("add proxy route for service", proxyRoute.Name)
-	fwd, err := forward.New()
+	fwd, err := forward.New(forward.PassHostHeader(proxyRoute.PassHostHeader))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create forward")
 	}
 
-	lb, err := roundrobin.New(fwd)
+	lbOpts := []roundrobin.LBOption{}
+	if proxyRoute.Sticky != nil {
+		lbOpts = append(lbOpts, roundrobin.EnableStickySession(roundrobin.NewStickySession(proxyRoute.Sticky.CookieName)))
+	}
+
+	lb, err := roundrobin.New(fwd, lbOpts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create roundrobin load balancer")
 	}
 
-	stream, err := stream.New(lb, stream.Retry(`IsNetworkError() && Attempts() < 2`))
+	for _, backend := range proxyRoute.Backends {
+		log.Infoln("register new backend for service", proxyRoute.Name, backend.URL)
+		err = lb.UpsertServer(backend.URL, roundrobin.Weight(weightOrDefault(backend.Weight)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create upsert server for %s", backend.URL.String())
+		}
+	}
+
+	top, breaker, err := ds.buildRouteChain(proxyRoute, lb)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to configure middleware chain for service %s", proxyRoute.Name)
+	}
+
+	// configure middleware for proxy backend
+	middleware := ds.createMiddleware(proxyRoute.Name, lb)
+	middleware.UseHandler(top)
+
+	ds.routeHandlers[proxyRoute.Name] = middleware
+	ds.routeDefs[proxyRoute.Name] = proxyRoute
+	ds.circuitBreakers[proxyRoute.Name] = breaker
+
+	if proxyRoute.HealthCheck != nil {
+		stop := make(chan struct{})
+		ds.healthCheckers[proxyRoute.Name] = stop
+		checker := newHealthChecker(ds, proxyRoute.Name, proxyRoute.HealthCheck, lb)
+		go checker.run(stop)
+	}
+
+	return lb, nil
+}
+
+// buildRouteChain composes the per-route middleware stack around lb in a
+// fixed order: stripprefix -> headers -> ratelimit -> connlimit ->
+// cbreaker -> buffer/retry -> roundrobin -> forward. Fields left at their zero value on
+// route are skipped, preserving today's default of a plain retrying
+// circuit breaker with no limits or header rewriting. It also returns the
+// circuit breaker itself so callers can surface its state (e.g. through
+// the admin API).
+func (ds *DefaultServer) buildRouteChain(route *ProxyRoute, lb *roundrobin.RoundRobin) (http.Handler, *cbreaker.CircuitBreaker, error) {
+	retryPredicate := "IsNetworkError()"
+	retryAttempts := 2
+	if route.Retry != nil {
+		if route.Retry.Predicate != "" {
+			retryPredicate = route.Retry.Predicate
+		}
+		retryAttempts = route.Retry.Attempts
+	}
+
+	retryBuffer, err := buffer.New(lb, buffer.Retry(fmt.Sprintf("(%s) && Attempts() < %d", retryPredicate, retryAttempts)))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create stream")
+		return nil, nil, errors.Wrap(err, "failed to create buffer")
 	}
 
-	circuitBreaker, err := cbreaker.New(stream, "NetworkErrorRatio() > 0.5")
+	breakerExpr := route.CircuitBreaker
+	if breakerExpr == "" {
+		breakerExpr = "NetworkErrorRatio() > 0.5"
+	}
+
+	breaker, err := cbreaker.New(retryBuffer, breakerExpr)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create circuit breaker")
+		return nil, nil, errors.Wrap(err, "failed to create circuit breaker")
 	}
+	var top http.Handler = breaker
 
-	for _, url := range proxyRoute.Backends {
-		log.Infoln("register new backend for service", proxyRoute.Name, url)
-		err = lb.UpsertServer(url)
+	if route.MaxConns > 0 {
+		extractor, err := utils.NewExtractor("client.ip")
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to create upsert server for %s", url.String())
+			return nil, nil, errors.Wrap(err, "failed to create connection limit extractor")
+		}
+		top, err = connlimit.New(top, extractor, route.MaxConns)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create connection limiter")
 		}
 	}
 
-	// configure middleware for proxy backend
-	middleware := ds.createMiddleware(lb)
-	middleware.UseHandler(circuitBreaker)
-	route, err := proxyRoute.Create(ds.router)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to configure route for service %s", proxyRoute.Name)
+	if route.RateLimit != nil {
+		rates := ratelimit.NewRateSet()
+		if err := rates.Add(route.RateLimit.Period, route.RateLimit.Average, route.RateLimit.Burst); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to configure rate limit")
+		}
+
+		extractor, err := utils.NewExtractor("client.ip")
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create rate limit extractor")
+		}
+		top, err = ratelimit.New(top, extractor, rates)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create rate limiter")
+		}
+	}
+
+	if len(route.RequestHeaders) > 0 || len(route.ResponseHeaders) > 0 {
+		top = NewHeaders(route.RequestHeaders, route.ResponseHeaders, top)
 	}
 
-	// configure route
-	route.Handler(middleware)
+	if len(route.StripPrefix) > 0 {
+		top = NewStripPrefix(route.StripPrefix, top)
+	}
 
-	return lb, nil
+	return top, breaker, nil
 }
 
-func (ds *DefaultServer) createMiddleware(lb *roundrobin.RoundRobin) *negroni.Negroni {
-	// copy midlewares and append 502 handler
-	length := len(ds.middleware)
-	middlewares := make([]negroni.Handler, length+1)
-	copy(middlewares, ds.middleware)
-	middlewares[length] = &BadGateway{lb}
+func (ds *DefaultServer) createMiddleware(routeName string, lb *roundrobin.RoundRobin) *negroni.Negroni {
+	middlewares := []negroni.Handler{}
+	if ds.adminMetrics != nil {
+		// the metrics middleware sits ahead of everything else so it sees
+		// the same status code and latency the client does.
+		middlewares = append(middlewares, ds.adminMetrics.Observe(routeName))
+	}
+	middlewares = append(middlewares, ds.middleware...)
+	middlewares = append(middlewares, &BadGateway{lb})
 
 	// configure middleware for proxy backend
 	return negroni.New(middlewares...)
 }
 
 // ConfigureProxyRoutes configures proxy routes. The method will configure a
-// roundrobin load balancer for each proxy route.
+// roundrobin load balancer for each proxy route, then rebuild and swap in a
+// fresh router on every entry point so the change takes effect atomically.
 func (ds *DefaultServer) ConfigureProxyRoutes(routes []*ProxyRoute) error {
 	log.Debugln("configure proxy routes")
 
+	ds.routesMu.Lock()
+	defer ds.routesMu.Unlock()
+
 	// handle new and update
 	for _, route := range routes {
 		lb := ds.proxyRoutes[route.Name]
 		if lb != nil {
-			err := ds.updateProxyRoute(route, lb)
-			if err != nil {
+			if err := ds.updateProxyRoute(route, lb); err != nil {
 				return errors.Wrapf(err, "failed to update proxy route for %s", route.Name)
 			}
 		} else {
@@ -157,37 +349,361 @@ func (ds *DefaultServer) ConfigureProxyRoutes(routes []*ProxyRoute) error {
 	}
 
 	// handle remove
-	for name, lb := range ds.proxyRoutes {
+	for name := range ds.proxyRoutes {
 		if !ContainsRoute(routes, name) {
-			// Remove route completly ?
-			route := ProxyRoute{Name: name, Backends: []*url.URL{}}
-			ds.updateProxyRoute(&route, lb)
+			delete(ds.proxyRoutes, name)
+			delete(ds.routeHandlers, name)
+			delete(ds.routeDefs, name)
+			delete(ds.circuitBreakers, name)
+
+			if stop, ok := ds.healthCheckers[name]; ok {
+				close(stop)
+				delete(ds.healthCheckers, name)
+			}
+			ds.healthMu.Lock()
+			delete(ds.backendHealth, name)
+			ds.healthMu.Unlock()
 		}
 	}
 
-	return nil
+	return ds.reloadLocked()
 }
 
 // GetProxyRoutes returns a slice of current configured proxy routes.
 func (ds *DefaultServer) GetProxyRoutes() []*ProxyRoute {
+	ds.routesMu.Lock()
+	defer ds.routesMu.Unlock()
+
 	routes := []*ProxyRoute{}
 	for name, lb := range ds.proxyRoutes {
-		backends := []*url.URL{}
+		backends := []Backend{}
 		for _, url := range lb.Servers() {
-			backends = append(backends, url)
+			backends = append(backends, Backend{URL: url})
 		}
 		routes = append(routes, &ProxyRoute{Name: name, Backends: backends})
 	}
 	return routes
 }
 
-// Start will start the default gateway server. After the server is started the
-// ConfigureProxyRoutes can be used to reconfigure the gateway.
+// GetCircuitBreakerState returns the log-friendly state of routeName's
+// circuit breaker (e.g. "CircuitBreaker(state=standby)"), and false if the
+// route doesn't exist.
+func (ds *DefaultServer) GetCircuitBreakerState(routeName string) (string, bool) {
+	ds.routesMu.Lock()
+	defer ds.routesMu.Unlock()
+
+	breaker, ok := ds.circuitBreakers[routeName]
+	if !ok {
+		return "", false
+	}
+	return breaker.String(), true
+}
+
+// GetBackendHealth returns the last known health of every backend checked
+// for routeName, keyed by backend URL. A backend that was never health
+// checked is absent from the result.
+func (ds *DefaultServer) GetBackendHealth(routeName string) map[string]bool {
+	ds.healthMu.Lock()
+	defer ds.healthMu.Unlock()
+
+	health := map[string]bool{}
+	for url, healthy := range ds.backendHealth[routeName] {
+		health[url] = healthy
+	}
+	return health
+}
+
+func (ds *DefaultServer) isBackendHealthy(routeName string, backend *url.URL) bool {
+	ds.healthMu.Lock()
+	defer ds.healthMu.Unlock()
+
+	healthy, known := ds.backendHealth[routeName][backend.String()]
+	if !known {
+		return true
+	}
+	return healthy
+}
+
+func (ds *DefaultServer) routeBackends(routeName string) []Backend {
+	ds.routesMu.Lock()
+	defer ds.routesMu.Unlock()
+
+	def, ok := ds.routeDefs[routeName]
+	if !ok {
+		return nil
+	}
+	return def.Backends
+}
+
+// setBackendHealth records the latest health check result for a backend and
+// returns whether it was previously considered healthy (a backend that was
+// never checked before is assumed healthy).
+func (ds *DefaultServer) setBackendHealth(routeName string, backend *url.URL, healthy bool) bool {
+	ds.healthMu.Lock()
+	defer ds.healthMu.Unlock()
+
+	routeHealth, ok := ds.backendHealth[routeName]
+	if !ok {
+		routeHealth = map[string]bool{}
+		ds.backendHealth[routeName] = routeHealth
+	}
+
+	wasHealthy, known := routeHealth[backend.String()]
+	if !known {
+		wasHealthy = true
+	}
+	routeHealth[backend.String()] = healthy
+	return wasHealthy
+}
+
+// Reload builds a fresh router from the current proxy routes for every
+// entry point and atomically swaps it in behind the entry point's
+// HandlerSwitcher, so in-flight requests keep running against the old
+// router while new requests are routed with the new one. Routes are
+// registered in Priority order (highest first, then Name) since mux
+// otherwise matches in insertion order, which is fragile across reloads.
+func (ds *DefaultServer) Reload() error {
+	ds.routesMu.Lock()
+	defer ds.routesMu.Unlock()
+
+	return ds.reloadLocked()
+}
+
+// reloadLocked is Reload's body, factored out so ConfigureProxyRoutes can
+// invoke it without recursively taking routesMu.
+func (ds *DefaultServer) reloadLocked() error {
+	defs := make([]*ProxyRoute, 0, len(ds.routeDefs))
+	for _, def := range ds.routeDefs {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Priority != defs[j].Priority {
+			return defs[i].Priority > defs[j].Priority
+		}
+		return defs[i].Name < defs[j].Name
+	})
+
+	for name, ep := range ds.entryPoints {
+		router := mux.NewRouter()
+		for _, def := range defs {
+			route, err := def.Create(router)
+			if err != nil {
+				return errors.Wrapf(err, "failed to configure route %s for entrypoint %s", def.Name, name)
+			}
+			route.Handler(ds.routeHandlers[def.Name])
+		}
+		ep.router = router
+		ep.switcher.Switch(router)
+	}
+	log.Infoln("reloaded gateway routes")
+	return nil
+}
+
+// Start will start every configured entry point. After the entry points are
+// started, ConfigureProxyRoutes can be used to reconfigure the gateway and
+// Reload/Stop can be triggered by SIGHUP/SIGINT/SIGTERM respectively. If
+// providers are configured, they are started alongside the entry points and
+// will keep calling ConfigureProxyRoutes on their own as the routes they
+// discover change. Start blocks until every entry point has stopped.
 func (ds *DefaultServer) Start() error {
-	if ds.configuration.CertFile != "" && ds.configuration.KeyFile != "" {
-		log.Infoln("starting https gateway server")
-		return ds.server.ListenAndServeTLS(ds.configuration.CertFile, ds.configuration.KeyFile)
+	if len(ds.configuration.Providers) > 0 {
+		pool := provider.NewPool(context.Background())
+		ds.providersMu.Lock()
+		ds.providerPool = pool
+		ds.providersMu.Unlock()
+		go ds.watchProviders(pool)
+	}
+
+	signal.Notify(ds.signalChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go ds.listenForSignals()
+
+	entryPoints := ds.allEntryPoints()
+	var wg sync.WaitGroup
+	errChan := make(chan error, len(entryPoints))
+	for _, ep := range entryPoints {
+		wg.Add(1)
+		go func(ep *serverEntryPoint) {
+			defer wg.Done()
+			log.Infoln("starting gateway entrypoint", ep.name, "on", ep.server.Addr)
+
+			var err error
+			if ep.server.TLSConfig != nil {
+				err = ep.server.ListenAndServeTLS("", "")
+			} else {
+				err = ep.server.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				errChan <- errors.Wrapf(err, "entrypoint %s failed", ep.name)
+			}
+		}(ep)
+	}
+
+	wg.Wait()
+	close(errChan)
+	for err := range errChan {
+		return err
+	}
+	return nil
+}
+
+// Stop gracefully drains every entry point, giving in-flight requests up to
+// timeout to complete before their connections are closed. Any running
+// providers are stopped alongside the entry points so their background
+// watch goroutines don't outlive the server.
+func (ds *DefaultServer) Stop(timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultDrainTimeout
+	}
+
+	ds.providersMu.Lock()
+	pool := ds.providerPool
+	ds.providersMu.Unlock()
+	if pool != nil {
+		pool.Stop()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var lastErr error
+	for _, ep := range ds.allEntryPoints() {
+		log.Infoln("draining gateway entrypoint", ep.name)
+		if err := ep.server.Shutdown(ctx); err != nil {
+			lastErr = errors.Wrapf(err, "failed to drain entrypoint %s", ep.name)
+			ep.server.Close()
+		}
+	}
+	return lastErr
+}
+
+// allEntryPoints returns every entry point the server listens on, including
+// the admin entry point when one is configured.
+func (ds *DefaultServer) allEntryPoints() []*serverEntryPoint {
+	entryPoints := make([]*serverEntryPoint, 0, len(ds.entryPoints)+1)
+	for _, ep := range ds.entryPoints {
+		entryPoints = append(entryPoints, ep)
+	}
+	if ds.admin != nil {
+		entryPoints = append(entryPoints, ds.admin)
+	}
+	return entryPoints
+}
+
+// listenForSignals translates SIGHUP into Reload and SIGINT/SIGTERM into a
+// graceful Stop.
+func (ds *DefaultServer) listenForSignals() {
+	for sig := range ds.signalChan {
+		switch sig {
+		case syscall.SIGHUP:
+			log.Infoln("received SIGHUP, reloading gateway routes")
+			if err := ds.Reload(); err != nil {
+				log.Errorln("failed to reload gateway routes:", err)
+			}
+		case syscall.SIGINT, syscall.SIGTERM:
+			log.Infoln("received", sig, "stopping gateway")
+			if err := ds.Stop(defaultDrainTimeout); err != nil {
+				log.Errorln("failed to stop gateway cleanly:", err)
+			}
+			return
+		}
+	}
+}
+
+// watchProviders starts every configured provider and merges the
+// ConfigMessages they publish into a single global configuration, applying
+// it to the router after a short quiet period so a burst of messages from
+// the same or different providers collapses into one reconfiguration. It
+// returns once pool is stopped.
+func (ds *DefaultServer) watchProviders(pool *provider.SafePool) {
+	for _, p := range ds.configuration.Providers {
+		p := p
+		go func() {
+			if err := p.Provide(ds.configChan, pool); err != nil {
+				log.Errorln("failed to start provider:", err)
+			}
+		}()
+	}
+
+	debounce := time.NewTimer(providerDebouncePeriod)
+	if !debounce.Stop() {
+		<-debounce.C
+	}
+
+	for {
+		select {
+		case message := <-ds.configChan:
+			log.Debugln("received configuration from provider", message.ProviderName)
+			ds.providersMu.Lock()
+			ds.providerConfigs[message.ProviderName] = message.Configuration
+			ds.providersMu.Unlock()
+			debounce.Reset(providerDebouncePeriod)
+		case <-debounce.C:
+			ds.reconcileProviders()
+		case <-pool.Done():
+			return
+		}
+	}
+}
+
+// reconcileProviders merges the latest snapshot from every provider into a
+// single route list and applies it.
+func (ds *DefaultServer) reconcileProviders() {
+	ds.providersMu.Lock()
+	routes := []*ProxyRoute{}
+	for _, providerRoutes := range ds.providerConfigs {
+		for _, route := range providerRoutes {
+			routes = append(routes, toProxyRoute(route))
+		}
+	}
+	ds.providersMu.Unlock()
+
+	if err := ds.ConfigureProxyRoutes(routes); err != nil {
+		log.Errorln("failed to reconfigure proxy routes from providers:", err)
+	}
+}
+
+// toProxyRoute expands the route shape a Provider discovers or reads into a
+// full ProxyRoute, copying every field provider.Route mirrors from it.
+func toProxyRoute(route *provider.Route) *ProxyRoute {
+	backends := make([]Backend, len(route.Backends))
+	for i, backend := range route.Backends {
+		backends[i] = Backend{URL: backend.URL, Weight: backend.Weight}
+	}
+
+	pr := &ProxyRoute{
+		Name:            route.Name,
+		Host:            route.Host,
+		Path:            route.Path,
+		Backends:        backends,
+		Rule:            route.Rule,
+		Priority:        route.Priority,
+		StripPrefix:     route.StripPrefix,
+		MaxConns:        route.MaxConns,
+		CircuitBreaker:  route.CircuitBreaker,
+		PassHostHeader:  route.PassHostHeader,
+		RequestHeaders:  route.RequestHeaders,
+		ResponseHeaders: route.ResponseHeaders,
+	}
+	if route.Sticky != nil {
+		pr.Sticky = &StickySpec{CookieName: route.Sticky.CookieName}
+	}
+	if route.HealthCheck != nil {
+		pr.HealthCheck = &HealthCheckSpec{
+			Path:         route.HealthCheck.Path,
+			Interval:     route.HealthCheck.Interval,
+			Timeout:      route.HealthCheck.Timeout,
+			ExpectStatus: route.HealthCheck.ExpectStatus,
+		}
+	}
+	if route.RateLimit != nil {
+		pr.RateLimit = &RateLimitSpec{
+			Average: route.RateLimit.Average,
+			Burst:   route.RateLimit.Burst,
+			Period:  route.RateLimit.Period,
+		}
+	}
+	if route.Retry != nil {
+		pr.Retry = &RetrySpec{Attempts: route.Retry.Attempts, Predicate: route.Retry.Predicate}
 	}
-	log.Infoln("starting http gateway server")
-	return ds.server.ListenAndServe()
+	return pr
 }
@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// Certificate pairs a certificate/key file with the SNI host name it should
+// be served for, allowing an HTTPS entry point to serve several hosts.
+type Certificate struct {
+	CertFile string
+	KeyFile  string
+	SNI      string
+}
+
+// EntryPointConfiguration describes a single address DefaultServer listens
+// on. An entry point with no Certificates serves plain HTTP; one with
+// Certificates serves HTTPS and picks a certificate per request using SNI.
+type EntryPointConfiguration struct {
+	Name         string
+	Address      string
+	Certificates []Certificate
+}
+
+// HandlerSwitcher is an http.Handler that delegates to another http.Handler
+// which can be swapped atomically, so a router can be rebuilt and put into
+// service without dropping the listener or in-flight connections.
+type HandlerSwitcher struct {
+	handler atomic.Value
+}
+
+// NewHandlerSwitcher creates a HandlerSwitcher initially delegating to
+// initial.
+func NewHandlerSwitcher(initial http.Handler) *HandlerSwitcher {
+	hs := &HandlerSwitcher{}
+	hs.Switch(initial)
+	return hs
+}
+
+// ServeHTTP delegates to the currently active handler.
+func (hs *HandlerSwitcher) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	hs.handler.Load().(http.Handler).ServeHTTP(rw, req)
+}
+
+// Switch atomically replaces the handler in service.
+func (hs *HandlerSwitcher) Switch(handler http.Handler) {
+	hs.handler.Store(handler)
+}
+
+// serverEntryPoint is a single listening address, its router, and the
+// *http.Server draining it.
+type serverEntryPoint struct {
+	name     string
+	router   *mux.Router
+	switcher *HandlerSwitcher
+	server   *http.Server
+}
+
+// newServerEntryPoint builds a serverEntryPoint from config, wiring up TLS
+// with SNI-based certificate selection when certificates are configured.
+func newServerEntryPoint(config EntryPointConfiguration) (*serverEntryPoint, error) {
+	router := mux.NewRouter()
+	switcher := NewHandlerSwitcher(router)
+
+	server := &http.Server{
+		Addr:    config.Address,
+		Handler: switcher,
+	}
+
+	if len(config.Certificates) > 0 {
+		tlsConfig, err := buildTLSConfig(config.Certificates)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to configure tls for entrypoint %s", config.Name)
+		}
+		server.TLSConfig = tlsConfig
+	}
+
+	return &serverEntryPoint{
+		name:     config.Name,
+		router:   router,
+		switcher: switcher,
+		server:   server,
+	}, nil
+}
+
+// buildTLSConfig loads every certificate and returns a tls.Config that
+// selects between them by SNI host name, falling back to the first
+// certificate when the client didn't request one or no match is found.
+func buildTLSConfig(certificates []Certificate) (*tls.Config, error) {
+	keyPairs := make(map[string]tls.Certificate, len(certificates))
+	order := make([]string, 0, len(certificates))
+
+	for _, cert := range certificates {
+		keyPair, err := tls.LoadX509KeyPair(cert.CertFile, cert.KeyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load certificate for %s", cert.SNI)
+		}
+		keyPairs[cert.SNI] = keyPair
+		order = append(order, cert.SNI)
+	}
+
+	return &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if keyPair, ok := keyPairs[hello.ServerName]; ok {
+				return &keyPair, nil
+			}
+			defaultKeyPair := keyPairs[order[0]]
+			return &defaultKeyPair, nil
+		},
+	}, nil
+}
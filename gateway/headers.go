@@ -0,0 +1,27 @@
+package gateway
+
+import "net/http"
+
+// headersHandler rewrites static headers on the request before it reaches
+// next, and on the response before it reaches the client.
+type headersHandler struct {
+	request  map[string]string
+	response map[string]string
+	next     http.Handler
+}
+
+// NewHeaders wraps next with a handler applying the RequestHeaders and
+// ResponseHeaders declared on a ProxyRoute.
+func NewHeaders(request, response map[string]string, next http.Handler) http.Handler {
+	return &headersHandler{request: request, response: response, next: next}
+}
+
+func (h *headersHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	for name, value := range h.request {
+		req.Header.Set(name, value)
+	}
+	for name, value := range h.response {
+		rw.Header().Set(name, value)
+	}
+	h.next.ServeHTTP(rw, req)
+}
@@ -0,0 +1,97 @@
+package gateway
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/vulcand/oxy/roundrobin"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHealthCheckInterval = 30 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+)
+
+// healthChecker periodically probes every backend of a single proxy route
+// and keeps its load balancer in sync with the result. It runs on its own
+// goroutine and never touches ConfigureProxyRoutes' reconciliation loop
+// directly; it records results through DefaultServer.setBackendHealth so
+// that loop can avoid re-adding a backend the checker has marked down.
+type healthChecker struct {
+	routeName string
+	spec      *HealthCheckSpec
+	lb        *roundrobin.RoundRobin
+	client    *http.Client
+	server    *DefaultServer
+}
+
+func newHealthChecker(server *DefaultServer, routeName string, spec *HealthCheckSpec, lb *roundrobin.RoundRobin) *healthChecker {
+	timeout := spec.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	return &healthChecker{
+		routeName: routeName,
+		spec:      spec,
+		lb:        lb,
+		client:    &http.Client{Timeout: timeout},
+		server:    server,
+	}
+}
+
+func (hc *healthChecker) run(stop <-chan struct{}) {
+	interval := hc.spec.Interval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			hc.check()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (hc *healthChecker) check() {
+	for _, backend := range hc.server.routeBackends(hc.routeName) {
+		healthy := hc.probe(backend.URL)
+		wasHealthy := hc.server.setBackendHealth(hc.routeName, backend.URL, healthy)
+		if healthy == wasHealthy {
+			continue
+		}
+
+		if healthy {
+			log.Infoln("backend recovered, re-registering", backend.URL, "for", hc.routeName)
+			hc.lb.UpsertServer(backend.URL, roundrobin.Weight(weightOrDefault(backend.Weight)))
+		} else {
+			log.Warnln("backend failed health check, removing", backend.URL, "from", hc.routeName)
+			hc.lb.RemoveServer(backend.URL)
+		}
+	}
+}
+
+func (hc *healthChecker) probe(backend *url.URL) bool {
+	target := backend.String() + hc.spec.Path
+
+	resp, err := hc.client.Get(target)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	expect := hc.spec.ExpectStatus
+	if expect == 0 {
+		expect = http.StatusOK
+	}
+	return resp.StatusCode == expect
+}
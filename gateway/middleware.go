@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/codegangsta/negroni"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// RequestIDHeader is the header used to correlate a request across logs.
+const RequestIDHeader = "X-Request-Id"
+
+type requestID struct{}
+
+// NewRequestID returns a middleware that stamps every request with a unique
+// X-Request-Id header, generating one when the client didn't send it.
+func NewRequestID() negroni.Handler {
+	return &requestID{}
+}
+
+func (r *requestID) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	id := req.Header.Get(RequestIDHeader)
+	if id == "" {
+		id = generateRequestID()
+		req.Header.Set(RequestIDHeader, id)
+	}
+	rw.Header().Set(RequestIDHeader, id)
+	next(rw, req)
+}
+
+func generateRequestID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return fmt.Sprintf("%x", buf)
+}
+
+// BadGateway is a terminal middleware that responds with 502 Bad Gateway
+// when a proxy route has no healthy backends left to forward to.
+type BadGateway struct {
+	lb *roundrobin.RoundRobin
+}
+
+func (b *BadGateway) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	if len(b.lb.Servers()) == 0 {
+		http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+	next(rw, req)
+}
@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// consulRetryBackoff is how long the blocking-query loop waits before
+// retrying after a failed catalog query, so a Consul outage doesn't turn
+// into a busy loop hammering it with requests.
+const consulRetryBackoff = 5 * time.Second
+
+// Consul is a Provider that watches the Consul catalog for healthy service
+// instances using blocking queries, one proxy route per service.
+type Consul struct {
+	Endpoint string
+	Domain   string
+}
+
+// Provide runs a blocking-query loop against the Consul catalog, publishing
+// a fresh configuration every time the catalog index changes.
+func (p *Consul) Provide(configChan chan<- ConfigMessage, pool *SafePool) error {
+	config := api.DefaultConfig()
+	if p.Endpoint != "" {
+		config.Address = p.Endpoint
+	}
+
+	client, err := api.NewClient(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create consul client")
+	}
+
+	pool.Go(func(ctx context.Context) {
+		var lastIndex uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			services, meta, err := client.Catalog().Services((&api.QueryOptions{
+				WaitIndex: lastIndex,
+			}).WithContext(ctx))
+			if err != nil {
+				log.Errorln("consul provider: failed to query catalog:", err)
+				if !sleepOrDone(ctx, consulRetryBackoff) {
+					return
+				}
+				continue
+			}
+			lastIndex = meta.LastIndex
+
+			routes, err := p.buildRoutes(client, services)
+			if err != nil {
+				log.Errorln("consul provider: failed to build routes:", err)
+				if !sleepOrDone(ctx, consulRetryBackoff) {
+					return
+				}
+				continue
+			}
+
+			configChan <- ConfigMessage{ProviderName: "consul", Configuration: routes}
+		}
+	})
+
+	return nil
+}
+
+// sleepOrDone waits for d, returning early and reporting false if ctx is
+// cancelled first.
+func sleepOrDone(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (p *Consul) buildRoutes(client *api.Client, services map[string][]string) ([]*Route, error) {
+	routes := []*Route{}
+	for name := range services {
+		entries, _, err := client.Health().Service(name, "", true, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to query health for service %s", name)
+		}
+
+		backends := []Backend{}
+		for _, entry := range entries {
+			backend, err := url.Parse(fmt.Sprintf("http://%s:%d", entry.Service.Address, entry.Service.Port))
+			if err != nil {
+				log.Warnln("consul provider: invalid backend address for service", name, ":", err)
+				continue
+			}
+			backends = append(backends, Backend{URL: backend})
+		}
+
+		if len(backends) == 0 {
+			continue
+		}
+
+		routes = append(routes, &Route{
+			Name:     name,
+			Host:     fmt.Sprintf("%s.%s", name, p.Domain),
+			Backends: backends,
+		})
+	}
+	return routes, nil
+}
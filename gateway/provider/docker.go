@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Docker is a Provider that watches the Docker daemon event stream and
+// turns running containers into proxy routes, one per container, named
+// after the container and matched on host "<container>.<Domain>".
+type Docker struct {
+	Endpoint string
+	Domain   string
+}
+
+// Provide lists the currently running containers, publishes them, then
+// watches the daemon's event stream to republish on every container
+// start/stop.
+func (p *Docker) Provide(configChan chan<- ConfigMessage, pool *SafePool) error {
+	client, err := docker.NewClient(p.Endpoint)
+	if err != nil {
+		return errors.Wrap(err, "failed to create docker client")
+	}
+
+	if err := p.publish(client, configChan); err != nil {
+		return err
+	}
+
+	events := make(chan *docker.APIEvents, 16)
+	if err := client.AddEventListener(events); err != nil {
+		return errors.Wrap(err, "failed to listen for docker events")
+	}
+
+	pool.Go(func(ctx context.Context) {
+		defer client.RemoveEventListener(events)
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				switch event.Status {
+				case "start", "die", "stop", "kill", "pause", "unpause":
+					if err := p.publish(client, configChan); err != nil {
+						log.Errorln("docker provider: failed to publish configuration:", err)
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func (p *Docker) publish(client *docker.Client, configChan chan<- ConfigMessage) error {
+	containers, err := client.ListContainers(docker.ListContainersOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list containers")
+	}
+
+	routes := []*Route{}
+	for _, container := range containers {
+		if len(container.Names) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(container.Names[0], "/")
+
+		inspect, err := client.InspectContainer(container.ID)
+		if err != nil {
+			log.Warnln("docker provider: failed to inspect container", name, ":", err)
+			continue
+		}
+
+		ip := inspect.NetworkSettings.IPAddress
+		if ip == "" {
+			continue
+		}
+
+		backend, err := url.Parse(fmt.Sprintf("http://%s", ip))
+		if err != nil {
+			log.Warnln("docker provider: invalid backend address for container", name, ":", err)
+			continue
+		}
+
+		routes = append(routes, &Route{
+			Name:     name,
+			Host:     fmt.Sprintf("%s.%s", name, p.Domain),
+			Backends: []Backend{{URL: backend}},
+		})
+	}
+
+	configChan <- ConfigMessage{ProviderName: "docker", Configuration: routes}
+	return nil
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// File is a Provider that reads proxy routes from a JSON file and watches
+// it for changes using fsnotify, republishing the whole file on every
+// write.
+type File struct {
+	Filename string
+}
+
+// Provide publishes the file's current contents, then watches it for
+// changes.
+func (p *File) Provide(configChan chan<- ConfigMessage, pool *SafePool) error {
+	if err := p.publish(configChan); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "failed to create file watcher")
+	}
+
+	if err := watcher.Add(p.Filename); err != nil {
+		return errors.Wrapf(err, "failed to watch %s", p.Filename)
+	}
+
+	pool.Go(func(ctx context.Context) {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				if err := p.publish(configChan); err != nil {
+					log.Errorln("file provider: failed to publish configuration:", err)
+				}
+			case watchErr, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Errorln("file provider: watcher error:", watchErr)
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func (p *File) publish(configChan chan<- ConfigMessage) error {
+	data, err := ioutil.ReadFile(p.Filename)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", p.Filename)
+	}
+
+	routes := []*Route{}
+	if err := json.Unmarshal(data, &routes); err != nil {
+		return errors.Wrapf(err, "failed to parse %s", p.Filename)
+	}
+
+	configChan <- ConfigMessage{ProviderName: "file", Configuration: routes}
+	return nil
+}
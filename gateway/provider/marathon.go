@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	marathon "github.com/gambol99/go-marathon"
+	"github.com/pkg/errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Marathon is a Provider that watches the Marathon /v2/events SSE stream and
+// turns running applications into proxy routes, one per application.
+type Marathon struct {
+	Endpoint string
+	Domain   string
+}
+
+// Provide publishes the currently running applications, then subscribes to
+// the Marathon event stream to republish on every application change.
+func (p *Marathon) Provide(configChan chan<- ConfigMessage, pool *SafePool) error {
+	config := marathon.NewDefaultConfig()
+	config.URL = p.Endpoint
+	config.EventsTransport = marathon.EventsTransportSSE
+
+	client, err := marathon.NewClient(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create marathon client")
+	}
+
+	if err := p.publish(client, configChan); err != nil {
+		return err
+	}
+
+	events, err := client.AddEventsListener(marathon.EventIDApplications)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen for marathon events")
+	}
+
+	pool.Go(func(ctx context.Context) {
+		defer client.RemoveEventsListener(events)
+		for {
+			select {
+			case _, ok := <-events:
+				if !ok {
+					return
+				}
+				if err := p.publish(client, configChan); err != nil {
+					log.Errorln("marathon provider: failed to publish configuration:", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func (p *Marathon) publish(client marathon.Marathon, configChan chan<- ConfigMessage) error {
+	applications, err := client.Applications(nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to list applications")
+	}
+
+	routes := []*Route{}
+	for _, app := range applications.Apps {
+		backends := []Backend{}
+		for _, task := range app.Tasks {
+			if len(task.Ports) == 0 {
+				continue
+			}
+			backend, err := url.Parse(fmt.Sprintf("http://%s:%d", task.Host, task.Ports[0]))
+			if err != nil {
+				log.Warnln("marathon provider: invalid backend address for app", app.ID, ":", err)
+				continue
+			}
+			backends = append(backends, Backend{URL: backend})
+		}
+
+		if len(backends) == 0 {
+			continue
+		}
+
+		routes = append(routes, &Route{
+			Name:     app.ID,
+			Host:     fmt.Sprintf("%s.%s", app.ID, p.Domain),
+			Backends: backends,
+		})
+	}
+
+	configChan <- ConfigMessage{ProviderName: "marathon", Configuration: routes}
+	return nil
+}
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"context"
+	"sync"
+)
+
+// SafePool lets a Provider spawn background goroutines that are guaranteed
+// to be cancelled and waited on together when the provider is stopped.
+type SafePool struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPool creates a SafePool bound to parentCtx; cancelling parentCtx also
+// stops every goroutine started through the pool.
+func NewPool(parentCtx context.Context) *SafePool {
+	ctx, cancel := context.WithCancel(parentCtx)
+	return &SafePool{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in its own goroutine, passing it the pool's context so fn can
+// observe cancellation and exit cleanly.
+func (p *SafePool) Go(fn func(ctx context.Context)) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		fn(p.ctx)
+	}()
+}
+
+// Stop cancels every goroutine started via Go and waits for them to return.
+func (p *SafePool) Stop() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// Done returns a channel that's closed once the pool is stopped, so callers
+// with their own loop tied to the pool's lifetime (rather than a goroutine
+// started via Go) can observe cancellation too.
+func (p *SafePool) Done() <-chan struct{} {
+	return p.ctx.Done()
+}
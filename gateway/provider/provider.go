@@ -0,0 +1,22 @@
+// Package provider lets DefaultServer discover proxy routes dynamically
+// from external sources such as a container engine, a KV store, or an
+// orchestrator event stream, instead of requiring the embedder to call
+// ConfigureProxyRoutes imperatively.
+package provider
+
+// ConfigMessage is emitted by a Provider whenever its view of the proxy
+// routes it is responsible for changes.
+type ConfigMessage struct {
+	ProviderName  string
+	Configuration []*Route
+}
+
+// Provider watches an external source of truth and publishes ConfigMessages
+// describing the proxy routes it discovers on configChan. Provide is
+// expected to start any background watch loop via pool and return once the
+// provider is ready; it must not block forever. The initial publish may
+// happen before Provide returns (Docker, Marathon, File) or from the watch
+// loop (Consul).
+type Provider interface {
+	Provide(configChan chan<- ConfigMessage, pool *SafePool) error
+}
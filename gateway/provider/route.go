@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"net/url"
+	"time"
+)
+
+// RateLimitSpec mirrors gateway.RateLimitSpec: a token-bucket rate limit of
+// Average and Burst requests per Period, per client.
+type RateLimitSpec struct {
+	Average int64
+	Burst   int64
+	Period  time.Duration
+}
+
+// RetrySpec mirrors gateway.RetrySpec: how many times a request is retried
+// against another backend, and under what condition.
+type RetrySpec struct {
+	Attempts  int
+	Predicate string
+}
+
+// Backend mirrors gateway.Backend: a single backend URL participating in a
+// route's load balancer, optionally weighted.
+type Backend struct {
+	URL    *url.URL
+	Weight int
+}
+
+// StickySpec mirrors gateway.StickySpec: pins a client to the backend it
+// was first routed to, using a cookie named CookieName.
+type StickySpec struct {
+	CookieName string
+}
+
+// HealthCheckSpec mirrors gateway.HealthCheckSpec: an active health check
+// polled on every backend.
+type HealthCheckSpec struct {
+	Path         string
+	Interval     time.Duration
+	Timeout      time.Duration
+	ExpectStatus int
+}
+
+// Route is the proxy route shape a Provider discovers or reads, mirroring
+// gateway.ProxyRoute field for field. DefaultServer expands it into a full
+// gateway.ProxyRoute when reconciling provider configuration. Route
+// duplicates gateway.ProxyRoute's fields rather than embedding it so
+// provider implementations don't need to import gateway, which would
+// otherwise create an import cycle with DefaultServer's use of this
+// package.
+type Route struct {
+	Name     string
+	Host     string
+	Path     string
+	Backends []Backend
+
+	Rule        string
+	Priority    int
+	StripPrefix []string
+
+	Sticky      *StickySpec
+	HealthCheck *HealthCheckSpec
+
+	MaxConns       int64
+	RateLimit      *RateLimitSpec
+	Retry          *RetrySpec
+	CircuitBreaker string
+	PassHostHeader bool
+
+	RequestHeaders  map[string]string
+	ResponseHeaders map[string]string
+}
@@ -0,0 +1,113 @@
+package gateway
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// RateLimitSpec configures a token-bucket rate limit: Average and Burst
+// requests per Period, per client.
+type RateLimitSpec struct {
+	Average int64
+	Burst   int64
+	Period  time.Duration
+}
+
+// RetrySpec configures how many times a request is retried against another
+// backend, and under what condition. Predicate is an oxy expression
+// ("IsNetworkError()", ...); when empty it defaults to "IsNetworkError()".
+type RetrySpec struct {
+	Attempts  int
+	Predicate string
+}
+
+// Backend is a single backend URL participating in a proxy route's load
+// balancer. Weight biases the round robin towards heavier backends; a
+// Weight of 0 is treated as 1.
+type Backend struct {
+	URL    *url.URL
+	Weight int
+}
+
+// StickySpec pins a client to the backend it was first routed to, using a
+// cookie named CookieName.
+type StickySpec struct {
+	CookieName string
+}
+
+// HealthCheckSpec configures an active health check: Path is polled on
+// every backend every Interval, and a response within Timeout matching
+// ExpectStatus marks the backend healthy. A zero Interval/Timeout/
+// ExpectStatus falls back to a 30s interval, 5s timeout and 200 OK.
+type HealthCheckSpec struct {
+	Path         string
+	Interval     time.Duration
+	Timeout      time.Duration
+	ExpectStatus int
+}
+
+// ProxyRoute describes a single proxied service: a name used to track it
+// across reconfigurations, a host/path rule matched against incoming
+// requests, and the set of backends traffic is load balanced across. The
+// middleware fields are all optional; their zero value preserves the
+// gateway's default behavior (a plain retrying circuit breaker, no limits,
+// no header rewriting, no stickiness, no active health checking).
+type ProxyRoute struct {
+	Name     string
+	Host     string
+	Path     string
+	Backends []Backend
+
+	// Rule is a ";"-separated matching DSL, e.g.
+	// "Host:foo.com;PathPrefix:/api;Method:GET,POST;Header:X-Token,.*".
+	// When set it takes precedence over Host/Path. See parseRule.
+	Rule string
+
+	// Priority orders routes within the router: a higher Priority is
+	// registered first, so it is tried first when more than one route
+	// would otherwise match a request. Routes with equal Priority are
+	// ordered by Name for determinism across reloads.
+	Priority int
+
+	// StripPrefix removes the first of these prefixes that matches the
+	// request path before it is forwarded, so backends see a clean path.
+	StripPrefix []string
+
+	Sticky      *StickySpec
+	HealthCheck *HealthCheckSpec
+
+	MaxConns       int64
+	RateLimit      *RateLimitSpec
+	Retry          *RetrySpec
+	CircuitBreaker string
+	PassHostHeader bool
+
+	RequestHeaders  map[string]string
+	ResponseHeaders map[string]string
+}
+
+// Create registers the proxy route on the given router and returns the
+// resulting mux.Route so the caller can attach a handler to it. When Rule
+// is set it is parsed into the matchers applied to the route; otherwise
+// the legacy Host/Path fields are used.
+func (pr *ProxyRoute) Create(router *mux.Router) (*mux.Route, error) {
+	route := router.NewRoute()
+
+	if pr.Rule != "" {
+		if err := parseRule(pr.Rule, route); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse rule for route %s", pr.Name)
+		}
+		return route, nil
+	}
+
+	if pr.Host != "" {
+		route = route.Host(pr.Host)
+	}
+	if pr.Path != "" {
+		route = route.Path(pr.Path)
+	}
+	return route, nil
+}
@@ -0,0 +1,70 @@
+package gateway
+
+import (
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// parseRule parses a Rule expression such as
+// "Host:foo.com;PathPrefix:/api;Method:GET,POST;Header:X-Token,.*" into a
+// sequence of matchers applied to route. Clauses are split on ";", then
+// each clause on the first ":"; since every clause constrains the same
+// mux.Route, they are ANDed together.
+func parseRule(rule string, route *mux.Route) error {
+	for _, clause := range strings.Split(rule, ";") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+
+		parts := strings.SplitN(clause, ":", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("invalid rule clause %q, expected Name:value", clause)
+		}
+
+		name := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		if err := applyRuleClause(route, name, value); err != nil {
+			return errors.Wrapf(err, "invalid rule clause %q", clause)
+		}
+	}
+	return nil
+}
+
+func applyRuleClause(route *mux.Route, name, value string) error {
+	switch name {
+	case "Host":
+		route.Host(value)
+	case "Path":
+		route.Path(value)
+	case "PathPrefix":
+		route.PathPrefix(value)
+	case "Method":
+		route.Methods(strings.Split(value, ",")...)
+	case "Header":
+		header, pattern, err := splitPair(value)
+		if err != nil {
+			return errors.Wrap(err, "header rule requires Header:name,pattern")
+		}
+		route.HeadersRegexp(header, pattern)
+	case "Query":
+		key, pattern, err := splitPair(value)
+		if err != nil {
+			return errors.Wrap(err, "query rule requires Query:name,value")
+		}
+		route.Queries(key, pattern)
+	default:
+		return errors.Errorf("unknown rule %q", name)
+	}
+	return nil
+}
+
+func splitPair(value string) (string, string, error) {
+	parts := strings.SplitN(value, ",", 2)
+	if len(parts) != 2 {
+		return "", "", errors.Errorf("expected a name and a value separated by \",\", got %q", value)
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
+}
@@ -0,0 +1,35 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+)
+
+// stripPrefixHandler removes the first of a set of prefixes matching the
+// request path before calling next, so backends see a clean path
+// regardless of which prefix the client used to reach them.
+type stripPrefixHandler struct {
+	prefixes []string
+	next     http.Handler
+}
+
+// NewStripPrefix wraps next with a handler that strips the first of
+// prefixes matching the request path.
+func NewStripPrefix(prefixes []string, next http.Handler) http.Handler {
+	return &stripPrefixHandler{prefixes: prefixes, next: next}
+}
+
+func (s *stripPrefixHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	for _, prefix := range s.prefixes {
+		rest := strings.TrimPrefix(req.URL.Path, prefix)
+		if len(rest) == len(req.URL.Path) {
+			continue
+		}
+		if !strings.HasPrefix(rest, "/") {
+			rest = "/" + rest
+		}
+		req.URL.Path = rest
+		break
+	}
+	s.next.ServeHTTP(rw, req)
+}
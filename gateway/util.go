@@ -0,0 +1,33 @@
+package gateway
+
+import "net/url"
+
+// ContainsURL reports whether urls contains u.
+func ContainsURL(urls []*url.URL, u *url.URL) bool {
+	for _, existing := range urls {
+		if existing.String() == u.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsBackendURL reports whether backends includes one with URL u.
+func ContainsBackendURL(backends []Backend, u *url.URL) bool {
+	for _, backend := range backends {
+		if backend.URL.String() == u.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsRoute reports whether routes contains a route named name.
+func ContainsRoute(routes []*ProxyRoute, name string) bool {
+	for _, route := range routes {
+		if route.Name == name {
+			return true
+		}
+	}
+	return false
+}